@@ -0,0 +1,38 @@
+package dlog
+
+import "context"
+
+type dlogContextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, retrievable with
+// FromContext or Ctx. If ctx already carries this exact logger, ctx is
+// returned unchanged.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	if existing, ok := ctx.Value(dlogContextKey{}).(Logger); ok && existing == logger {
+		return ctx
+	}
+	return context.WithValue(ctx, dlogContextKey{}, logger)
+}
+
+// FromContext returns the Logger stored in ctx by NewContext, or the package
+// default logger if ctx carries none.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(dlogContextKey{}).(Logger); ok {
+		return logger
+	}
+	return dlogger
+}
+
+// Ctx is a short alias for FromContext, meant for chaining at the log site:
+// dlog.Ctx(ctx).Info("handled request", dlog.Int("status", 200)).
+func Ctx(ctx context.Context) Logger {
+	return FromContext(ctx)
+}
+
+// WithFields clones the Logger stored in ctx (or the package default), adds
+// fields to it, and returns a new context carrying the enriched logger. It
+// lets middleware attach request-scoped fields (request id, trace id, user
+// id) as a request descends the call stack.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(fields...))
+}
@@ -0,0 +1,60 @@
+package dlog
+
+// The *Depth functions behave like their plain package-level counterparts
+// (Debug, Info, ...), but attribute the caller annotation depth frames
+// further up the stack than the direct caller. Use them to build thin
+// wrappers around dlog, e.g. an audit logger that always calls
+// dlog.InfoDepth(1, ...) so log lines point at its own caller rather than
+// at the wrapper itself.
+
+func DebugDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(DebugLevel, msg, fields, depth)
+}
+
+func InfoDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(InfoLevel, msg, fields, depth)
+}
+
+func WarnDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(WarnLevel, msg, fields, depth)
+}
+
+func ErrorDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(ErrorLevel, msg, fields, depth)
+}
+
+func PanicDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(PanicLevel, msg, fields, depth)
+	panic(msg)
+}
+
+func FatalDepth(depth int, msg string, fields ...Field) {
+	dlogger.log(FatalLevel, msg, fields, depth)
+	_exit(1)
+}
+
+func (log *logger) DebugDepth(depth int, msg string, fields ...Field) {
+	log.log(DebugLevel, msg, fields, depth)
+}
+
+func (log *logger) InfoDepth(depth int, msg string, fields ...Field) {
+	log.log(InfoLevel, msg, fields, depth)
+}
+
+func (log *logger) WarnDepth(depth int, msg string, fields ...Field) {
+	log.log(WarnLevel, msg, fields, depth)
+}
+
+func (log *logger) ErrorDepth(depth int, msg string, fields ...Field) {
+	log.log(ErrorLevel, msg, fields, depth)
+}
+
+func (log *logger) PanicDepth(depth int, msg string, fields ...Field) {
+	log.log(PanicLevel, msg, fields, depth)
+	panic(msg)
+}
+
+func (log *logger) FatalDepth(depth int, msg string, fields ...Field) {
+	log.log(FatalLevel, msg, fields, depth)
+	_exit(1)
+}
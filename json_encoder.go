@@ -0,0 +1,285 @@
+package dlog
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var jsonPool = sync.Pool{New: func() interface{} {
+	return &jsonEncoder{
+		bytes: make([]byte, 0, 1024),
+	}
+}}
+
+type jsonEncoder struct {
+	bytes      []byte
+	timeFmt    string
+	timeKey    string
+	msgKey     string
+	levelKey   string
+	callerKey  string
+	firstField bool
+}
+
+// NewJSONEncoder creates a fast, low-allocation JSON encoder whose output is
+// intended for machine consumption (log shippers, indexers, etc). By default,
+// the encoder uses RFC3339-formatted timestamps and the "ts"/"level"/"caller"/
+// "msg" keys.
+func NewJSONEncoder(options ...JSONOption) *jsonEncoder {
+	enc := jsonPool.Get().(*jsonEncoder)
+	enc.truncate()
+	enc.timeFmt = time.RFC3339
+	enc.timeKey = "ts"
+	enc.msgKey = "msg"
+	enc.levelKey = "level"
+	enc.callerKey = "caller"
+	enc.firstField = true
+	for _, opt := range options {
+		opt.apply(enc)
+	}
+	return enc
+}
+
+func (enc *jsonEncoder) Free() {
+	jsonPool.Put(enc)
+}
+
+func (enc *jsonEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.bytes = appendEscapedJSONString(enc.bytes, val)
+}
+
+func (enc *jsonEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+}
+
+func (enc *jsonEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *jsonEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+}
+
+func (enc *jsonEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *jsonEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+}
+
+func (enc *jsonEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '"')
+	enc.bytes = append(enc.bytes, "0x"...)
+	enc.bytes = strconv.AppendUint(enc.bytes, uint64(val), 16)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *jsonEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, 64)
+}
+
+func (enc *jsonEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, '{')
+	nested := enc.firstField
+	enc.firstField = true
+	err := obj.MarshalLog(enc)
+	enc.firstField = nested
+	enc.bytes = append(enc.bytes, '}')
+	return err
+}
+
+func (enc *jsonEncoder) AddObject(key string, obj interface{}) error {
+	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	return nil
+}
+
+func (enc *jsonEncoder) Clone() Encoder {
+	clone := jsonPool.Get().(*jsonEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	clone.timeFmt = enc.timeFmt
+	clone.timeKey = enc.timeKey
+	clone.msgKey = enc.msgKey
+	clone.levelKey = enc.levelKey
+	clone.callerKey = enc.callerKey
+	clone.firstField = enc.firstField
+	return clone
+}
+
+func (enc *jsonEncoder) WriteEntry(sink io.Writer, caller string, line int, msg string, lvl Level, t time.Time) error {
+	final := jsonPool.Get().(*jsonEncoder)
+	final.truncate()
+	final.firstField = true
+
+	final.bytes = append(final.bytes, '{')
+	final.addKey(enc.levelKey)
+	final.bytes = appendEscapedJSONString(final.bytes, jsonLevelString(lvl))
+
+	if enc.timeFmt != "" {
+		final.addKey(enc.timeKey)
+		final.bytes = append(final.bytes, '"')
+		final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
+		final.bytes = append(final.bytes, '"')
+	}
+
+	final.addKey(enc.callerKey)
+	final.bytes = appendEscapedJSONString(final.bytes, caller+":"+strconv.Itoa(line))
+
+	final.addKey(enc.msgKey)
+	final.bytes = appendEscapedJSONString(final.bytes, msg)
+
+	if len(enc.bytes) > 0 {
+		final.bytes = append(final.bytes, ',')
+		final.bytes = append(final.bytes, enc.bytes...)
+	}
+	final.bytes = append(final.bytes, '}', '\n')
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	}
+	return nil
+}
+
+func (enc *jsonEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+}
+
+func (enc *jsonEncoder) addKey(key string) {
+	if !enc.firstField {
+		enc.bytes = append(enc.bytes, ',')
+	}
+	enc.firstField = false
+	enc.bytes = appendEscapedJSONString(enc.bytes, key)
+	enc.bytes = append(enc.bytes, ':')
+}
+
+func jsonLevelString(lvl Level) string {
+	switch lvl {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case PanicLevel:
+		return "panic"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return strconv.Itoa(int(lvl))
+	}
+}
+
+const hex = "0123456789abcdef"
+
+// appendEscapedJSONString appends s to dst as a quoted, escaped JSON string.
+func appendEscapedJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			if start < i {
+				dst = append(dst, s[start:i]...)
+			}
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	if start < len(s) {
+		dst = append(dst, s[start:]...)
+	}
+	dst = append(dst, '"')
+	return dst
+}
+
+// A JSONOption is used to set options for a JSON encoder.
+type JSONOption interface {
+	apply(*jsonEncoder)
+}
+
+type jsonOptionFunc func(*jsonEncoder)
+
+func (opt jsonOptionFunc) apply(enc *jsonEncoder) {
+	opt(enc)
+}
+
+// JSONTimeFormat sets the format for log timestamps, using the same layout
+// strings supported by time.Parse.
+func JSONTimeFormat(layout string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.timeFmt = layout
+	})
+}
+
+// JSONNoTime omits timestamps from the serialized log entries.
+func JSONNoTime() JSONOption {
+	return JSONTimeFormat("")
+}
+
+// JSONTimeKey overrides the default "ts" key used for the entry's timestamp.
+func JSONTimeKey(key string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.timeKey = key
+	})
+}
+
+// JSONMessageKey overrides the default "msg" key used for the log message.
+func JSONMessageKey(key string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.msgKey = key
+	})
+}
+
+// JSONLevelKey overrides the default "level" key used for the log level.
+func JSONLevelKey(key string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.levelKey = key
+	})
+}
+
+// JSONCallerKey overrides the default "caller" key used for the caller
+// annotation.
+func JSONCallerKey(key string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.callerKey = key
+	})
+}
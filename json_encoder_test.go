@@ -0,0 +1,26 @@
+package dlog
+
+import "testing"
+
+func TestAppendEscapedJSONString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"hello", `"hello"`},
+		{`quote"here`, `"quote\"here"`},
+		{`back\slash`, `"back\\slash"`},
+		{"line\nbreak", `"line\nbreak"`},
+		{"tab\ttab", `"tab\ttab"`},
+		{"carriage\rreturn", `"carriage\rreturn"`},
+		{"bell\x07byte", "\"bell\\u0007byte\""},
+		{"unicode: é中", "\"unicode: é中\""},
+		{"", `""`},
+	}
+
+	for _, c := range cases {
+		if got := string(appendEscapedJSONString(nil, c.in)); got != c.want {
+			t.Errorf("appendEscapedJSONString(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package dlog
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -25,6 +26,12 @@ type Logger interface {
 	// Create a child logger, and optionally add some context to that logger.
 	With(...Field) Logger
 
+	// V reports whether verbose logging at the given level is enabled for the
+	// caller, based on the global verbosity set by SetV and any more specific
+	// pattern configured via SetVModule. Use it to gate expensive debug
+	// logging without recompiling: V(2).Info("cache miss", dlog.String("key", k)).
+	V(level int) Verbose
+
 	// Check returns a CheckedMessage if logging a message at the specified level
 	// is enabled. It's a completely optional optimization; in high-performance
 	// applications, Check can help avoid allocating a slice to hold fields.
@@ -44,14 +51,27 @@ type Logger interface {
 	// If the logger is in development mode (via the Development option), DFatal
 	// logs at the Fatal level. Otherwise, it logs at the Error level.
 	DFatal(string, ...Field)
+
+	// The Depth variants behave like their plain counterparts, but attribute
+	// the caller annotation depth frames further up the stack. Use them to
+	// build thin wrappers (audit loggers, request loggers) without every log
+	// line pointing at the wrapper's own file:line.
+	DebugDepth(depth int, msg string, fields ...Field)
+	InfoDepth(depth int, msg string, fields ...Field)
+	WarnDepth(depth int, msg string, fields ...Field)
+	ErrorDepth(depth int, msg string, fields ...Field)
+	PanicDepth(depth int, msg string, fields ...Field)
+	FatalDepth(depth int, msg string, fields ...Field)
 }
 
 type logger struct{ Meta }
 
 var (
-	dlogger    *logger
-	syncFile   *os.File
-	syncTicker *time.Ticker
+	dlogger       *logger
+	syncFile      *os.File
+	syncTicker    *time.Ticker
+	rotatingFiles []*rotatingFile
+	netSinks      []*NetSink
 )
 
 // New constructs a logger that uses the provided encoder. By default, the
@@ -60,17 +80,44 @@ var (
 //
 // Options can change the log level, the output location, the initial fields
 // that should be added as context, and many other behaviors.
-func Init(filepath string) error {
-	f, err := os.OpenFile(filepath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0777)
-	if err != nil {
-		return err
+func Init(filepath string, opts ...Option) error {
+	cfg := options{maxSize: defaultMaxSize}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+
+	enc := cfg.encoder
+	if enc == nil {
+		enc = NewTextEncoder()
+	}
+	dlogger = &logger{Meta: MakeMeta(enc)}
+	if cfg.sampler != nil {
+		dlogger.SetSampler(cfg.sampler)
+	}
+
+	switch {
+	case cfg.output != nil:
+		dlogger.Output = cfg.output
+	case cfg.logDir != "":
+		out, files := newLeveledOutput(cfg.logDir, cfg.maxSize, cfg.maxBackups, cfg.maxAgeDays)
+		dlogger.Output = out
+		rotatingFiles = files
+		watchSIGHUP()
+	default:
+		f, err := os.OpenFile(filepath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0777)
+		if err != nil {
+			return err
+		}
+		dlogger.Output = newLockedWriteSyncer(f)
+		syncFile = f
+		go syncEntry(f)
 	}
-	dlogger = &logger{
-		Meta: MakeMeta(NewTextEncoder()),
+
+	if cfg.remoteURL != "" {
+		sink := NewNetSink(cfg.remoteURL, cfg.remoteOpts...)
+		dlogger.Output = multiWriteSyncer{dlogger.Output, sink}
+		netSinks = append(netSinks, sink)
 	}
-	dlogger.Output = newLockedWriteSyncer(f)
-	syncFile = f
-	go syncEntry(f)
 	return nil
 }
 
@@ -82,48 +129,85 @@ func syncEntry(f *os.File) {
 }
 
 func Debug(msg string, fields ...Field) {
-	dlogger.log(DebugLevel, msg, fields)
+	dlogger.log(DebugLevel, msg, fields, 0)
 }
 
 func Info(msg string, fields ...Field) {
-	dlogger.log(InfoLevel, msg, fields)
+	dlogger.log(InfoLevel, msg, fields, 0)
 }
 
 func Warn(msg string, fields ...Field) {
-	dlogger.log(WarnLevel, msg, fields)
+	dlogger.log(WarnLevel, msg, fields, 0)
 }
 
 func Error(msg string, fields ...Field) {
-	dlogger.log(ErrorLevel, msg, fields)
+	dlogger.log(ErrorLevel, msg, fields, 0)
 }
 
 func Panic(msg string, fields ...Field) {
-	dlogger.log(PanicLevel, msg, fields)
+	dlogger.log(PanicLevel, msg, fields, 0)
 	panic(msg)
 }
 
 func Fatal(msg string, fields ...Field) {
-	dlogger.log(FatalLevel, msg, fields)
+	dlogger.log(FatalLevel, msg, fields, 0)
 	_exit(1)
 }
 
 func Close() {
-	syncTicker.Stop()
-	syncFile.Sync()
-	syncFile.Close()
+	if syncTicker != nil {
+		syncTicker.Stop()
+	}
+	if syncFile != nil {
+		syncFile.Sync()
+		syncFile.Close()
+	}
+	for _, f := range rotatingFiles {
+		f.Sync()
+		f.Close()
+	}
+	for _, s := range netSinks {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		s.Shutdown(ctx)
+		cancel()
+	}
 }
 
-func (log *logger) log(lvl Level, msg string, fields []Field) {
+// Rotate forces every rotating log file configured via WithLogDir to close
+// its current file and start a new one. It's safe to call from a signal
+// handler; dlog already does so on SIGHUP.
+func Rotate() error {
+	for _, f := range rotatingFiles {
+		if err := f.Rotate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// log emits a single entry. depth is the number of additional stack frames
+// to skip past the direct caller of log when attributing the caller
+// annotation: 0 for a normal Debug/Info/... call, N for a thin wrapper N
+// frames removed from the real log site (see DebugDepth, InfoDepth, etc).
+func (log *logger) log(lvl Level, msg string, fields []Field, depth int) {
 	if !(lvl >= log.Level()) {
 		return
 	}
+	if log.sampler != nil && !log.sampler.Allow(lvl, msg) {
+		return
+	}
 
 	temp := log.Encoder.Clone()
 	addFields(temp, fields)
 
-	caller, line := CallerName1()
+	caller, line := callerName(3 + depth)
+
+	sink := log.Output
+	if router, ok := sink.(levelRouter); ok {
+		sink = router.forLevel(lvl)
+	}
 
-	if err := temp.WriteEntry(log.Output, caller, line, msg, lvl, time.Now()); err != nil {
+	if err := temp.WriteEntry(sink, caller, line, msg, lvl, time.Now()); err != nil {
 		log.internalError(err.Error())
 	}
 	temp.Free()
@@ -165,3 +249,13 @@ func CallerName1() (string, int) {
 	}
 	return path.Base(file), line
 }
+
+// callerName is a depth-parameterized version of CallerName1, used by log so
+// that the *Depth helpers can shift the skip count past their own wrappers.
+func callerName(skip int) (string, int) {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "", 0
+	}
+	return path.Base(file), line
+}
@@ -16,7 +16,8 @@ type Meta struct {
 	Output      WriteSyncer
 	ErrorOutput WriteSyncer
 
-	lvl int32
+	lvl     int32
+	sampler Sampler
 }
 
 // MakeMeta returns a new meta struct with sensible defaults: logging at
@@ -48,3 +49,12 @@ func (m Meta) Clone() Meta {
 	m.Encoder = m.Encoder.Clone()
 	return m
 }
+
+// SetSampler installs a Sampler that's consulted before any encoding work
+// happens, protecting downstream sinks from floods. Pass nil to disable
+// sampling. Clones created via Meta.Clone share the same Sampler, so rate
+// decisions for a given (level, message) pair stay consistent across a
+// logger and its children.
+func (m *Meta) SetSampler(s Sampler) {
+	m.sampler = s
+}
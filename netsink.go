@@ -0,0 +1,311 @@
+package dlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultNetBatchSize  = 100
+	defaultNetFlushEvery = 2 * time.Second
+	defaultNetBufferSize = 10000
+	defaultNetHighWater  = 8000
+	minNetBackoff        = 250 * time.Millisecond
+	maxNetBackoff        = 30 * time.Second
+)
+
+// NetSink is a WriteSyncer that buffers encoded log entries in memory and
+// ships them in batches to an HTTP(S) collector, without blocking the
+// caller's log call path: Write only appends to the buffer, and a background
+// goroutine does the actual upload. Entries are framed as NDJSON and
+// gzip-compressed. On upload failure it backs off exponentially (250ms up to
+// 30s, with jitter), and once the buffer grows past a high-water mark it
+// tees new entries to os.Stderr so operators aren't blind during an outage.
+type NetSink struct {
+	url    string
+	client *http.Client
+
+	batchSize  int
+	flushEvery time.Duration
+	capacity   int
+	highWater  int
+
+	mu          sync.Mutex
+	buf         [][]byte
+	dropped     int64
+	shutdownCtx context.Context // set by Shutdown before stopCtx is canceled
+
+	flush   chan struct{}
+	stopCtx context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// A NetSinkOption configures a NetSink returned by NewNetSink.
+type NetSinkOption interface {
+	apply(*NetSink)
+}
+
+type netSinkOptionFunc func(*NetSink)
+
+func (f netSinkOptionFunc) apply(s *NetSink) { f(s) }
+
+// WithBatchSize sets how many buffered entries NetSink uploads per HTTP
+// request. It defaults to 100.
+func WithBatchSize(n int) NetSinkOption {
+	return netSinkOptionFunc(func(s *NetSink) { s.batchSize = n })
+}
+
+// WithFlushInterval sets how often NetSink uploads a batch even if it hasn't
+// filled up. It defaults to 2s.
+func WithFlushInterval(d time.Duration) NetSinkOption {
+	return netSinkOptionFunc(func(s *NetSink) { s.flushEvery = d })
+}
+
+// WithBufferSize sets the maximum number of unsent entries NetSink holds in
+// memory. Once full, the oldest entries are dropped to make room for new
+// ones. It defaults to 10000.
+func WithBufferSize(n int) NetSinkOption {
+	return netSinkOptionFunc(func(s *NetSink) { s.capacity = n })
+}
+
+// WithHighWaterMark sets the buffered-entry count above which NetSink also
+// tees new entries to os.Stderr, so logs stay visible while the collector is
+// unreachable. It defaults to 8000.
+func WithHighWaterMark(n int) NetSinkOption {
+	return netSinkOptionFunc(func(s *NetSink) { s.highWater = n })
+}
+
+// WithHTTPClient overrides the *http.Client used to upload batches.
+func WithHTTPClient(client *http.Client) NetSinkOption {
+	return netSinkOptionFunc(func(s *NetSink) { s.client = client })
+}
+
+// NewNetSink creates a NetSink that uploads to url and starts its background
+// flush loop.
+func NewNetSink(url string, opts ...NetSinkOption) *NetSink {
+	stopCtx, cancel := context.WithCancel(context.Background())
+	s := &NetSink{
+		url:        url,
+		client:     http.DefaultClient,
+		batchSize:  defaultNetBatchSize,
+		flushEvery: defaultNetFlushEvery,
+		capacity:   defaultNetBufferSize,
+		highWater:  defaultNetHighWater,
+		flush:      make(chan struct{}, 1),
+		stopCtx:    stopCtx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	go s.loop()
+	return s
+}
+
+// Write appends an already-encoded entry to the buffer and returns
+// immediately; it never makes a network call itself. If the buffer is full,
+// the oldest entry is dropped to make room.
+func (s *NetSink) Write(p []byte) (int, error) {
+	entry := append([]byte(nil), p...)
+
+	s.mu.Lock()
+	if len(s.buf) >= s.capacity {
+		s.buf = s.buf[1:]
+		s.dropped++
+	}
+	s.buf = append(s.buf, entry)
+	overHighWater := len(s.buf) > s.highWater
+	s.mu.Unlock()
+
+	if overHighWater {
+		os.Stderr.Write(p)
+	}
+
+	select {
+	case s.flush <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Sync requests an immediate flush attempt; it doesn't block waiting for the
+// upload to complete.
+func (s *NetSink) Sync() error {
+	select {
+	case s.flush <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Shutdown stops the background flush loop and drains any buffered entries,
+// retrying failed uploads with backoff until either the buffer is empty or
+// ctx is done. A batch that's still undelivered when ctx expires is put back
+// at the front of the buffer rather than discarded. Shutdown itself always
+// waits for the drain to actually finish (it's bounded by ctx internally), so
+// callers never observe a partially-drained buffer.
+func (s *NetSink) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdownCtx = ctx
+	s.mu.Unlock()
+	s.cancel()
+	<-s.done
+	return ctx.Err()
+}
+
+func (s *NetSink) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushBatch(s.stopCtx)
+		case <-s.flush:
+			s.flushBatch(s.stopCtx)
+		case <-s.stopCtx.Done():
+			s.drain()
+			return
+		}
+	}
+}
+
+// drain retries flushing the buffer, bounded by the deadline Shutdown was
+// given (or indefinitely if NetSink was torn down some other way).
+func (s *NetSink) drain() {
+	s.mu.Lock()
+	ctx := s.shutdownCtx
+	s.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for s.bufferedCount() > 0 {
+		s.flushBatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *NetSink) bufferedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buf)
+}
+
+func (s *NetSink) takeBatch() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) == 0 {
+		return nil
+	}
+	n := s.batchSize
+	if n > len(s.buf) {
+		n = len(s.buf)
+	}
+	batch := s.buf[:n]
+	s.buf = s.buf[n:]
+	return batch
+}
+
+// flushBatch takes one batch off the buffer and uploads it, retrying with
+// backoff until it succeeds or ctx is done. A batch that still can't be
+// delivered by the time ctx is done is put back on the buffer rather than
+// dropped.
+func (s *NetSink) flushBatch(ctx context.Context) {
+	batch := s.takeBatch()
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := gzipNDJSON(batch)
+	if err != nil {
+		return
+	}
+
+	if err := s.uploadWithRetry(ctx, body); err != nil {
+		s.requeue(batch)
+	}
+}
+
+func (s *NetSink) uploadWithRetry(ctx context.Context, body []byte) error {
+	backoff := minNetBackoff
+	for {
+		if err := s.upload(ctx, body); err == nil {
+			return nil
+		}
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > maxNetBackoff {
+			backoff = maxNetBackoff
+		}
+	}
+}
+
+// requeue puts a batch that failed to upload during drain back at the front
+// of the buffer, so it's the next thing retried (or, if the process really is
+// going away, at least isn't silently lost before the caller can see
+// s.dropped). If this pushes the buffer over capacity, the oldest entries are
+// dropped to make room, same as Write does.
+func (s *NetSink) requeue(batch [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = append(batch, s.buf...)
+	if over := len(s.buf) - s.capacity; over > 0 {
+		s.buf = s.buf[over:]
+		s.dropped += int64(over)
+	}
+}
+
+func (s *NetSink) upload(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dlog: netsink upload failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func gzipNDJSON(entries [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	for _, e := range entries {
+		if _, err := gw.Write(e); err != nil {
+			gw.Close()
+			return nil, err
+		}
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
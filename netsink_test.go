@@ -0,0 +1,112 @@
+package dlog
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingRoundTripper fails the first failCount requests, then succeeds.
+type failingRoundTripper struct {
+	failCount int32
+	attempts  int32
+}
+
+func (rt *failingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt32(&rt.attempts, 1)
+	if n <= atomic.LoadInt32(&rt.failCount) {
+		return &http.Response{StatusCode: 500, Body: http.NoBody}, nil
+	}
+	return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+}
+
+func newTestNetSink(rt http.RoundTripper) *NetSink {
+	return NewNetSink("http://example.invalid/logs",
+		WithHTTPClient(&http.Client{Transport: rt}),
+		WithFlushInterval(time.Hour),
+	)
+}
+
+// hangingRoundTripper simulates a request that never gets a response (e.g. a
+// stalled connection) unless the request's context is canceled.
+type hangingRoundTripper struct{}
+
+func (hangingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestNetSinkShutdownRetriesUntilDeadline(t *testing.T) {
+	rt := &failingRoundTripper{failCount: 1000}
+	s := newTestNetSink(rt)
+	s.Write([]byte(`{"msg":"hello"}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := s.Shutdown(ctx)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown() err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("Shutdown() returned after %v, want it to have retried until near the deadline", elapsed)
+	}
+	if atomic.LoadInt32(&rt.attempts) < 2 {
+		t.Errorf("upload attempts = %d, want at least 2 (i.e. it retried)", rt.attempts)
+	}
+}
+
+func TestNetSinkShutdownRequeuesUndeliveredBatch(t *testing.T) {
+	rt := &failingRoundTripper{failCount: 1000}
+	s := newTestNetSink(rt)
+	s.Write([]byte(`{"msg":"hello"}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown() err = %v, want context.DeadlineExceeded", err)
+	}
+
+	if got := s.bufferedCount(); got != 1 {
+		t.Errorf("bufferedCount() after failed drain = %d, want 1 (requeued, not discarded)", got)
+	}
+}
+
+func TestNetSinkShutdownCancelsInFlightUpload(t *testing.T) {
+	s := newTestNetSink(hangingRoundTripper{})
+	s.Write([]byte(`{"msg":"hello"}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Shutdown(ctx) }()
+
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Shutdown() err = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown() did not return within 2s of a 200ms deadline; an in-flight upload isn't being canceled by ctx")
+	}
+}
+
+func TestNetSinkShutdownSucceedsOnceUploadsRecover(t *testing.T) {
+	rt := &failingRoundTripper{failCount: 2}
+	s := newTestNetSink(rt)
+	s.Write([]byte(`{"msg":"hello"}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() err = %v, want nil", err)
+	}
+	if got := s.bufferedCount(); got != 0 {
+		t.Errorf("bufferedCount() after successful drain = %d, want 0", got)
+	}
+}
@@ -0,0 +1,102 @@
+package dlog
+
+// options collects the settings supplied via Option values passed to Init.
+type options struct {
+	encoder Encoder
+	output  WriteSyncer
+
+	logDir     string
+	maxSize    int64
+	maxBackups int
+	maxAgeDays int
+
+	remoteURL  string
+	remoteOpts []NetSinkOption
+
+	sampler Sampler
+}
+
+// An Option configures the behavior of the package-level logger created by
+// Init.
+type Option interface {
+	apply(*options)
+}
+
+type optionFunc func(*options)
+
+func (f optionFunc) apply(opts *options) {
+	f(opts)
+}
+
+// WithEncoder overrides the default text encoder, letting callers choose e.g.
+// NewJSONEncoder() for structured output without editing package internals.
+func WithEncoder(enc Encoder) Option {
+	return optionFunc(func(opts *options) {
+		opts.encoder = enc
+	})
+}
+
+// WithOutput overrides the default file WriteSyncer that Init would otherwise
+// open from the supplied path. It's mutually exclusive with WithLogDir.
+func WithOutput(ws WriteSyncer) Option {
+	return optionFunc(func(opts *options) {
+		opts.output = ws
+	})
+}
+
+// WithLogDir switches Init from writing a single file to a rotating,
+// per-level file set (one file each for INFO+, WARN+, and ERROR+) inside dir,
+// modeled on glog's log files. It's mutually exclusive with WithOutput. See
+// WithMaxSize, WithMaxBackups, and WithMaxAgeDays for the other rotation
+// knobs, and Rotate for forcing rotation on demand (e.g. from a SIGHUP
+// handler).
+func WithLogDir(dir string) Option {
+	return optionFunc(func(opts *options) {
+		opts.logDir = dir
+	})
+}
+
+// WithMaxSize sets the size, in bytes, a rotated log file is allowed to reach
+// before dlog starts a new one. Only meaningful alongside WithLogDir; it
+// defaults to 1800MB, matching glog.
+func WithMaxSize(bytes int64) Option {
+	return optionFunc(func(opts *options) {
+		opts.maxSize = bytes
+	})
+}
+
+// WithMaxBackups caps the number of old rotated files dlog keeps per level;
+// the oldest are removed first. Zero, the default, keeps them all.
+func WithMaxBackups(n int) Option {
+	return optionFunc(func(opts *options) {
+		opts.maxBackups = n
+	})
+}
+
+// WithMaxAgeDays removes rotated files older than the given number of days.
+// Zero, the default, disables age-based cleanup.
+func WithMaxAgeDays(n int) Option {
+	return optionFunc(func(opts *options) {
+		opts.maxAgeDays = n
+	})
+}
+
+// WithRemote ships logs to a collector at url via a NetSink, in addition to
+// whatever local output Init already configured (the single file by
+// default, or the WithLogDir rotating files). See NetSinkOption for the
+// available tuning knobs (batch size, flush interval, buffer size, ...).
+func WithRemote(url string, opts ...NetSinkOption) Option {
+	return optionFunc(func(o *options) {
+		o.remoteURL = url
+		o.remoteOpts = opts
+	})
+}
+
+// WithSampling installs a Sampler (see NewSampler, Tick, First, Thereafter)
+// so a tight loop hitting a single log line can't flood the configured
+// sinks.
+func WithSampling(opts ...SampleOption) Option {
+	return optionFunc(func(o *options) {
+		o.sampler = NewSampler(opts...)
+	})
+}
@@ -0,0 +1,253 @@
+package dlog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultMaxSize is the size, in bytes, a rotated log file is allowed to
+// reach before dlog starts a new one. It matches glog's default of 1800MB.
+const defaultMaxSize = 1024 * 1024 * 1800
+
+var pid = os.Getpid()
+
+// rotatingFile is a WriteSyncer that rotates itself, glog-style: it starts a
+// new file before a write would take the current one past maxSize, on the
+// first write after the date changes, or when Rotate is called explicitly
+// (e.g. from a SIGHUP handler), and keeps a
+// "<program>.<level>" symlink pointing at the newest file.
+type rotatingFile struct {
+	mu sync.Mutex
+
+	dir      string
+	program  string
+	host     string
+	userName string
+	level    string
+
+	maxSize    int64
+	maxBackups int
+	maxAgeDays int
+
+	file    *os.File
+	nbytes  int64
+	created time.Time
+}
+
+func newRotatingFile(dir, level string, maxSize int64, maxBackups, maxAgeDays int) *rotatingFile {
+	host, userName := hostUser()
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	return &rotatingFile{
+		dir:        dir,
+		program:    filepath.Base(os.Args[0]),
+		host:       host,
+		userName:   userName,
+		level:      level,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAgeDays: maxAgeDays,
+	}
+}
+
+func hostUser() (host, userName string) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknownhost"
+	}
+	userName = "unknownuser"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		userName = u.Username
+	}
+	return host, userName
+}
+
+// logName returns the name of a new log file and the symlink that should
+// point at it, e.g. "myprog.host.root.log.INFO.20260725-140000.4242" and
+// "myprog.INFO".
+func (f *rotatingFile) logName(t time.Time) (name, link string) {
+	name = fmt.Sprintf("%s.%s.%s.log.%s.%s.%d",
+		f.program, f.host, f.userName, f.level, t.Format("20060102-150405"), pid)
+	link = fmt.Sprintf("%s.%s", f.program, f.level)
+	return name, link
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil || f.nbytes+int64(len(p)) > f.maxSize || !sameDay(f.created, time.Now()) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.nbytes += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Rotate closes the current file, if any, and opens a new one, moving the
+// "<program>.<level>" symlink to point at it. It's safe to call from a
+// signal handler.
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		f.file.Close()
+	}
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name, link := f.logName(now)
+	fullPath := filepath.Join(f.dir, name)
+	newFile, err := os.OpenFile(fullPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	f.file = newFile
+	f.nbytes = 0
+	f.created = now
+
+	linkPath := filepath.Join(f.dir, link)
+	os.Remove(linkPath)
+	if err := os.Symlink(name, linkPath); err != nil {
+		// Not every filesystem supports symlinks; losing the "current" file
+		// convenience shouldn't stop logging.
+		fmt.Fprintln(os.Stderr, "dlog: could not symlink current log file:", err)
+	}
+
+	f.removeOldBackups()
+	return nil
+}
+
+func sameDay(a, b time.Time) bool {
+	y1, m1, d1 := a.Date()
+	y2, m2, d2 := b.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+func (f *rotatingFile) removeOldBackups() {
+	if f.maxBackups <= 0 && f.maxAgeDays <= 0 {
+		return
+	}
+	pattern := fmt.Sprintf("%s.%s.%s.log.%s.*", f.program, f.host, f.userName, f.level)
+	matches, err := filepath.Glob(filepath.Join(f.dir, pattern))
+	if err != nil {
+		return
+	}
+	// Log file names embed a yyyymmdd-hhmmss timestamp, so lexical order is
+	// chronological order.
+	sort.Strings(matches)
+
+	if f.maxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -f.maxAgeDays)
+		kept := matches[:0]
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(m)
+				continue
+			}
+			kept = append(kept, m)
+		}
+		matches = kept
+	}
+
+	// The newest match is the file rotate just opened; it isn't a backup.
+	if f.maxBackups > 0 && len(matches) > f.maxBackups+1 {
+		for _, m := range matches[:len(matches)-f.maxBackups-1] {
+			os.Remove(m)
+		}
+	}
+}
+
+// leveledOutput is a WriteSyncer that fans out to per-level rotating files:
+// the INFO file receives INFO and above, the WARN file receives WARN and
+// above, and the ERROR file receives only ERROR and above, matching glog's
+// cascading severity files.
+type leveledOutput struct {
+	info, warn, error *rotatingFile
+}
+
+func newLeveledOutput(dir string, maxSize int64, maxBackups, maxAgeDays int) (*leveledOutput, []*rotatingFile) {
+	info := newRotatingFile(dir, "INFO", maxSize, maxBackups, maxAgeDays)
+	warn := newRotatingFile(dir, "WARN", maxSize, maxBackups, maxAgeDays)
+	errFile := newRotatingFile(dir, "ERROR", maxSize, maxBackups, maxAgeDays)
+	return &leveledOutput{info: info, warn: warn, error: errFile}, []*rotatingFile{info, warn, errFile}
+}
+
+// Write satisfies WriteSyncer for callers that write without level context;
+// it lands in the INFO file, same as glog.
+func (o *leveledOutput) Write(p []byte) (int, error) {
+	return o.info.Write(p)
+}
+
+func (o *leveledOutput) Sync() error {
+	if err := o.info.Sync(); err != nil {
+		return err
+	}
+	if err := o.warn.Sync(); err != nil {
+		return err
+	}
+	return o.error.Sync()
+}
+
+// forLevel returns the WriteSyncer(s) that a message at lvl should be
+// written to.
+func (o *leveledOutput) forLevel(lvl Level) WriteSyncer {
+	switch {
+	case lvl >= ErrorLevel:
+		return multiWriteSyncer{o.info, o.warn, o.error}
+	case lvl >= WarnLevel:
+		return multiWriteSyncer{o.info, o.warn}
+	default:
+		return o.info
+	}
+}
+
+var sighupOnce sync.Once
+
+// watchSIGHUP arranges for all rotating log files to rotate on SIGHUP.
+func watchSIGHUP() {
+	sighupOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+		go func() {
+			for range ch {
+				Rotate()
+			}
+		}()
+	})
+}
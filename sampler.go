@@ -0,0 +1,143 @@
+package dlog
+
+import (
+	"sync"
+	"time"
+)
+
+// A Sampler decides whether a log call should proceed, before any encoding
+// work happens. It lets logger.log bail out ahead of Encoder.Clone, so a
+// dropped entry costs nothing beyond the Allow check itself.
+type Sampler interface {
+	Allow(lvl Level, msg string) bool
+}
+
+type sampleKey struct {
+	lvl Level
+	msg string
+}
+
+type sampleCounter struct {
+	resetAt time.Time
+	count   uint64
+}
+
+// countSampler allows the first `first` entries per (level, message) per
+// `tick`, then 1 of every `thereafter` until the tick resets. It's the
+// default Sampler returned by NewSampler.
+type countSampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+
+	mu       sync.Mutex
+	counters map[sampleKey]*sampleCounter
+}
+
+// NewSampler builds a Sampler that allows the first 100 entries of each
+// distinct (level, message) pair every second, then 1 of every 100
+// thereafter. Use Tick, First, and Thereafter to change those defaults.
+func NewSampler(opts ...SampleOption) Sampler {
+	s := &countSampler{
+		tick:       time.Second,
+		first:      100,
+		thereafter: 100,
+		counters:   make(map[sampleKey]*sampleCounter),
+	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	return s
+}
+
+func (s *countSampler) Allow(lvl Level, msg string) bool {
+	key := sampleKey{lvl, msg}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || now.After(c.resetAt) {
+		c = &sampleCounter{resetAt: now.Add(s.tick)}
+		s.counters[key] = c
+	}
+	c.count++
+
+	if c.count <= s.first {
+		return true
+	}
+	return (c.count-s.first)%s.thereafter == 0
+}
+
+// A SampleOption configures a Sampler built by NewSampler.
+type SampleOption interface {
+	apply(*countSampler)
+}
+
+type sampleOptionFunc func(*countSampler)
+
+func (f sampleOptionFunc) apply(s *countSampler) { f(s) }
+
+// Tick sets how often each (level, message) counter resets. It defaults to
+// one second.
+func Tick(d time.Duration) SampleOption {
+	return sampleOptionFunc(func(s *countSampler) {
+		s.tick = d
+	})
+}
+
+// First sets how many entries of each (level, message) pair are allowed
+// through per tick before thinning kicks in. It defaults to 100.
+func First(n uint64) SampleOption {
+	return sampleOptionFunc(func(s *countSampler) {
+		s.first = n
+	})
+}
+
+// Thereafter sets the thinning rate applied once First is exceeded: 1 of
+// every n entries is allowed through. It defaults to 100.
+func Thereafter(n uint64) SampleOption {
+	return sampleOptionFunc(func(s *countSampler) {
+		s.thereafter = n
+	})
+}
+
+// RateLimit wraps ws in a WriteSyncer that allows at most perSec writes per
+// second, silently dropping the rest. It caps raw write volume to a sink
+// (e.g. a rate-limited network endpoint) independent of any Sampler decision
+// made upstream in logger.log. perSec <= 0 means unlimited, and RateLimit
+// returns ws unwrapped.
+func RateLimit(ws WriteSyncer, perSec int) WriteSyncer {
+	if perSec <= 0 {
+		return ws
+	}
+	return &rateLimitedWriteSyncer{
+		ws:       ws,
+		interval: time.Second / time.Duration(perSec),
+	}
+}
+
+type rateLimitedWriteSyncer struct {
+	ws       WriteSyncer
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (r *rateLimitedWriteSyncer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	now := time.Now()
+	if now.Before(r.next) {
+		r.mu.Unlock()
+		return len(p), nil
+	}
+	r.next = now.Add(r.interval)
+	r.mu.Unlock()
+	return r.ws.Write(p)
+}
+
+func (r *rateLimitedWriteSyncer) Sync() error {
+	return r.ws.Sync()
+}
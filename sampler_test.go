@@ -0,0 +1,41 @@
+package dlog
+
+import "testing"
+
+func TestCountSamplerAllowsFirstThenThins(t *testing.T) {
+	s := NewSampler(First(2), Thereafter(3)).(*countSampler)
+
+	var allowed int
+	for i := 0; i < 8; i++ {
+		if s.Allow(InfoLevel, "hello") {
+			allowed++
+		}
+	}
+	// First 2 allowed, then 1 of every 3 of the remaining 6 (i.e. calls 5 and 8).
+	if want := 4; allowed != want {
+		t.Errorf("allowed = %d, want %d", allowed, want)
+	}
+}
+
+func TestCountSamplerTracksKeysIndependently(t *testing.T) {
+	s := NewSampler(First(1), Thereafter(100)).(*countSampler)
+
+	if !s.Allow(InfoLevel, "a") {
+		t.Error("first call for a distinct key should be allowed")
+	}
+	if !s.Allow(WarnLevel, "a") {
+		t.Error("same message at a different level is a distinct key and should be allowed")
+	}
+	if s.Allow(InfoLevel, "a") {
+		t.Error("second call for the same (level, msg) past First should be thinned")
+	}
+}
+
+func TestRateLimitZeroOrNegativeIsUnlimited(t *testing.T) {
+	ws := &fakeWriteSyncer{n: 1}
+	for _, perSec := range []int{0, -1} {
+		if got := RateLimit(ws, perSec); got != WriteSyncer(ws) {
+			t.Errorf("RateLimit(ws, %d) = %v, want ws unwrapped", perSec, got)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package dlog
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbose is returned by V and gates a single log site on whether verbose
+// logging is currently enabled for it. The zero value logs nothing.
+type Verbose struct {
+	enabled bool
+	log     *logger
+}
+
+// Info logs at InfoLevel if the Verbose value is enabled, and is a no-op
+// otherwise.
+func (v Verbose) Info(msg string, fields ...Field) {
+	if v.enabled {
+		v.log.log(InfoLevel, msg, fields, 0)
+	}
+}
+
+// V reports whether verbose logging at the given level is enabled for the
+// caller. See SetV and SetVModule.
+func V(level int) Verbose {
+	return Verbose{enabled: verboseEnabled(level), log: dlogger}
+}
+
+func (log *logger) V(level int) Verbose {
+	return Verbose{enabled: verboseEnabled(level), log: log}
+}
+
+var (
+	globalV       int32
+	vmoduleRules  atomic.Value // []vmoduleRule
+	verbosityByPC sync.Map     // uintptr -> int32
+)
+
+type vmoduleRule struct {
+	pattern   *regexp.Regexp
+	pathBased bool
+	level     int32
+}
+
+// SetV sets the default verbosity level used by V when no more specific
+// SetVModule pattern matches the caller. It's safe to call concurrently.
+func SetV(level int) {
+	atomic.StoreInt32(&globalV, int32(level))
+	verbosityByPC.Range(func(key, _ interface{}) bool {
+		verbosityByPC.Delete(key)
+		return true
+	})
+}
+
+// SetVModule configures per-file or per-path verbosity thresholds from a
+// comma-separated list of pattern=level pairs, e.g.
+//
+//	SetVModule("net/*=3,cache.go=2,pkg/db/**=4")
+//
+// Patterns may be a bare filename glob (matched against the caller's base
+// file name, e.g. "cache.go") or a path glob (matched against the caller's
+// full file path, e.g. "pkg/db/**"). "*" matches any run of characters
+// within a path segment; "**" matches across segments. The first matching
+// pattern, in the order given, wins; callers that match nothing fall back to
+// the level set by SetV.
+func SetVModule(spec string) error {
+	var rules []vmoduleRule
+	if spec != "" {
+		for _, clause := range strings.Split(spec, ",") {
+			clause = strings.TrimSpace(clause)
+			if clause == "" {
+				continue
+			}
+			eq := strings.LastIndex(clause, "=")
+			if eq < 0 {
+				return fmt.Errorf("dlog: invalid vmodule clause %q: missing '='", clause)
+			}
+			pat, lvlStr := clause[:eq], clause[eq+1:]
+			lvl, err := strconv.Atoi(lvlStr)
+			if err != nil {
+				return fmt.Errorf("dlog: invalid vmodule level in %q: %v", clause, err)
+			}
+			re, pathBased, err := compileVGlob(pat)
+			if err != nil {
+				return fmt.Errorf("dlog: invalid vmodule pattern %q: %v", pat, err)
+			}
+			rules = append(rules, vmoduleRule{pattern: re, pathBased: pathBased, level: int32(lvl)})
+		}
+	}
+	vmoduleRules.Store(rules)
+	verbosityByPC.Range(func(key, _ interface{}) bool {
+		verbosityByPC.Delete(key)
+		return true
+	})
+	return nil
+}
+
+// compileVGlob turns a vmodule glob into a regexp, and reports whether the
+// pattern is path-based (contains a "/") as opposed to a bare filename.
+//
+// A bare filename pattern (e.g. "cache.go") is anchored at both ends, since
+// it's matched against just the caller's base file name and should match it
+// exactly. A path pattern (e.g. "pkg/db/**") is anchored only at the end:
+// runtime.Func.FileLine returns the absolute build path (e.g.
+// "/root/myproject/pkg/db/conn.go" unless built with -trimpath), so anchoring
+// the start too would mean the pattern could essentially never match. Instead
+// the pattern is required to start either at the beginning of the path or
+// right after a "/", so "pkg/db/**" matches ".../pkg/db/conn.go" but not
+// ".../mypkg/db/conn.go".
+//
+// "*" matches any run of characters within a path segment; "**" matches
+// across segments.
+func compileVGlob(pattern string) (re *regexp.Regexp, pathBased bool, err error) {
+	pathBased = strings.Contains(pattern, "/")
+
+	var buf strings.Builder
+	if pathBased {
+		buf.WriteString(`(?:^|/)`)
+	} else {
+		buf.WriteByte('^')
+	}
+	for i := 0; i < len(pattern); {
+		if pattern[i] == '*' {
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				buf.WriteString(".*")
+				i += 2
+				continue
+			}
+			buf.WriteString("[^/]*")
+			i++
+			continue
+		}
+		buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		i++
+	}
+	buf.WriteByte('$')
+	re, err = regexp.Compile(buf.String())
+	return re, pathBased, err
+}
+
+// verboseEnabled reports whether V(level) should log, based on the immediate
+// caller of V or Logger.V (skip=2: this function, then V/Logger.V, then the
+// caller). Per-PC results are cached in verbosityByPC, so past the
+// runtime.Caller lookup the steady-state cost is one sync.Map Load.
+func verboseEnabled(level int) bool {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return int32(level) <= atomic.LoadInt32(&globalV)
+	}
+	if cached, ok := verbosityByPC.Load(pc); ok {
+		return int32(level) <= cached.(int32)
+	}
+	threshold := verbosityThresholdForPC(pc)
+	verbosityByPC.Store(pc, threshold)
+	return int32(level) <= threshold
+}
+
+func verbosityThresholdForPC(pc uintptr) int32 {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return atomic.LoadInt32(&globalV)
+	}
+	file, _ := fn.FileLine(pc)
+	base := path.Base(file)
+
+	if rules, ok := vmoduleRules.Load().([]vmoduleRule); ok {
+		for _, r := range rules {
+			if r.pathBased {
+				if r.pattern.MatchString(file) {
+					return r.level
+				}
+			} else if r.pattern.MatchString(base) {
+				return r.level
+			}
+		}
+	}
+	return atomic.LoadInt32(&globalV)
+}
@@ -0,0 +1,48 @@
+package dlog
+
+import "testing"
+
+func TestCompileVGlobPathPatterns(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"net/*", "/home/user/proj/net/client.go", true},
+		{"net/*", "/home/user/proj/othernet/client.go", false},
+		{"pkg/db/**", "/root/myproject/pkg/db/conn.go", true},
+		{"pkg/db/**", "/root/myproject/pkg/db/sub/conn.go", true},
+		// A path segment boundary is required before the pattern, so
+		// "pkg/db/**" must not match inside "mypkg/db/...".
+		{"pkg/db/**", "/root/myproject/mypkg/db/conn.go", false},
+	}
+
+	for _, c := range cases {
+		re, pathBased, err := compileVGlob(c.pattern)
+		if err != nil {
+			t.Fatalf("compileVGlob(%q): %v", c.pattern, err)
+		}
+		if !pathBased {
+			t.Fatalf("compileVGlob(%q): expected pathBased=true", c.pattern)
+		}
+		if got := re.MatchString(c.path); got != c.want {
+			t.Errorf("compileVGlob(%q).MatchString(%q) = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
+
+func TestCompileVGlobFilenamePatterns(t *testing.T) {
+	re, pathBased, err := compileVGlob("cache.go")
+	if err != nil {
+		t.Fatalf("compileVGlob: %v", err)
+	}
+	if pathBased {
+		t.Fatal("compileVGlob(\"cache.go\"): expected pathBased=false")
+	}
+	if !re.MatchString("cache.go") {
+		t.Error("expected exact base name match")
+	}
+	if re.MatchString("mycache.go") {
+		t.Error("bare filename pattern must not match a different file whose name happens to end the same way")
+	}
+}
@@ -0,0 +1,53 @@
+package dlog
+
+// With returns a child logger with fields added to its context. The
+// returned Logger shares the parent's level, encoder configuration, and
+// output, but every subsequent log call also carries fields.
+func (log *logger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return log
+	}
+	child := &logger{Meta: log.Meta.Clone()}
+	addFields(child.Encoder, fields)
+	return child
+}
+
+func (log *logger) Log(lvl Level, msg string, fields ...Field) {
+	log.log(lvl, msg, fields, 0)
+}
+
+func (log *logger) Debug(msg string, fields ...Field) {
+	log.log(DebugLevel, msg, fields, 0)
+}
+
+func (log *logger) Info(msg string, fields ...Field) {
+	log.log(InfoLevel, msg, fields, 0)
+}
+
+func (log *logger) Warn(msg string, fields ...Field) {
+	log.log(WarnLevel, msg, fields, 0)
+}
+
+func (log *logger) Error(msg string, fields ...Field) {
+	log.log(ErrorLevel, msg, fields, 0)
+}
+
+func (log *logger) Panic(msg string, fields ...Field) {
+	log.log(PanicLevel, msg, fields, 0)
+	panic(msg)
+}
+
+func (log *logger) Fatal(msg string, fields ...Field) {
+	log.log(FatalLevel, msg, fields, 0)
+	_exit(1)
+}
+
+// DFatal logs at FatalLevel in development mode, and at ErrorLevel otherwise.
+func (log *logger) DFatal(msg string, fields ...Field) {
+	if log.Development {
+		log.log(FatalLevel, msg, fields, 0)
+		_exit(1)
+		return
+	}
+	log.log(ErrorLevel, msg, fields, 0)
+}
@@ -56,6 +56,61 @@ func (s *lockedWriteSyncer) Sync() error {
 	return err
 }
 
+// levelRouter is implemented by WriteSyncers that fan a single log entry out
+// to different underlying sinks depending on its level, e.g. the per-level
+// rotating files created by WithLogDir.
+type levelRouter interface {
+	forLevel(Level) WriteSyncer
+}
+
+// multiWriteSyncer fans a single write out to every constituent WriteSyncer.
+// It attempts all of them, returns the first error encountered (if any), and
+// returns the smallest n reported by any constituent, so that callers relying
+// on io.Writer's "n < len(p) means incomplete write" contract (as
+// Encoder.WriteEntry does) still notice a short write from any one of them.
+type multiWriteSyncer []WriteSyncer
+
+func (ws multiWriteSyncer) Write(p []byte) (int, error) {
+	n := len(p)
+	var firstErr error
+	for _, w := range ws {
+		wn, err := w.Write(p)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if wn < n {
+			n = wn
+		}
+	}
+	return n, firstErr
+}
+
+func (ws multiWriteSyncer) Sync() error {
+	var firstErr error
+	for _, w := range ws {
+		if err := w.Sync(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// forLevel lets a multiWriteSyncer wrap a levelRouter (e.g. the per-level
+// files from WithLogDir) without losing its cascading behavior: constituents
+// that route by level are asked for their level-specific sink, and the rest
+// are passed through unchanged for every level.
+func (ws multiWriteSyncer) forLevel(lvl Level) WriteSyncer {
+	out := make(multiWriteSyncer, len(ws))
+	for i, w := range ws {
+		if router, ok := w.(levelRouter); ok {
+			out[i] = router.forLevel(lvl)
+		} else {
+			out[i] = w
+		}
+	}
+	return out
+}
+
 type writerWrapper struct {
 	io.Writer
 }
@@ -0,0 +1,38 @@
+package dlog
+
+import "testing"
+
+// fakeWriteSyncer reports a fixed n (instead of len(p)) from Write, to
+// simulate a short write.
+type fakeWriteSyncer struct {
+	n int
+}
+
+func (f *fakeWriteSyncer) Write(p []byte) (int, error) { return f.n, nil }
+func (f *fakeWriteSyncer) Sync() error                 { return nil }
+
+func TestMultiWriteSyncerWriteReturnsShortestN(t *testing.T) {
+	p := make([]byte, 10)
+	ws := multiWriteSyncer{&fakeWriteSyncer{n: 1}, &fakeWriteSyncer{n: 10}}
+
+	n, err := ws.Write(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Write() n = %d, want 1 (the shortest constituent write)", n)
+	}
+}
+
+func TestMultiWriteSyncerWriteFullWrite(t *testing.T) {
+	p := make([]byte, 10)
+	ws := multiWriteSyncer{&fakeWriteSyncer{n: 10}, &fakeWriteSyncer{n: 10}}
+
+	n, err := ws.Write(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len(p) {
+		t.Errorf("Write() n = %d, want %d", n, len(p))
+	}
+}